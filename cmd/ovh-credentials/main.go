@@ -0,0 +1,68 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+// Command ovh-credentials bootstraps an OVH consumer key for a DNSProvider secret
+// without the out-of-band curl dance against /auth/credential: it requests a key
+// scoped to DNS zone management, prints the validation URL for a human to approve,
+// waits for the approval, and emits a ready-to-apply Kubernetes Secret manifest.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gardener/external-dns-management/pkg/controller/provider/ovhcloud"
+)
+
+func main() {
+	endpoint := flag.String("endpoint", "ovh-eu", "OVH API endpoint, e.g. ovh-eu, ovh-ca, ovh-us")
+	appKey := flag.String("application-key", "", "OVH application key")
+	appSecret := flag.String("application-secret", "", "OVH application secret")
+	secretName := flag.String("secret-name", "ovh-credentials", "name of the generated Secret manifest")
+	namespace := flag.String("namespace", "default", "namespace of the generated Secret manifest")
+	flag.Parse()
+
+	if *appKey == "" || *appSecret == "" {
+		fmt.Fprintln(os.Stderr, "application-key and application-secret are required")
+		os.Exit(1)
+	}
+
+	consumerKey, validationURL, err := ovhcloud.RequestConsumerKey(context.Background(), *endpoint, *appKey, *appSecret, ovhcloud.DefaultZoneAccessRules)
+	if validationURL != "" {
+		fmt.Fprintf(os.Stderr, "Approve this consumer key at: %s\n", validationURL)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error requesting consumer key: %s\n", err)
+		os.Exit(1)
+	}
+
+	manifest := fmt.Sprintf(`apiVersion: v1
+kind: Secret
+metadata:
+  name: %s
+  namespace: %s
+type: Opaque
+stringData:
+  OVH_ENDPOINT: %q
+  OVH_APPLICATION_KEY: %q
+  OVH_APPLICATION_SECRET: %q
+  OVH_CONSUMER_KEY: %q
+`, *secretName, *namespace, *endpoint, *appKey, *appSecret, consumerKey)
+
+	fmt.Println(manifest)
+}
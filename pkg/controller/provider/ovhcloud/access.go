@@ -1,8 +1,8 @@
 /*
- * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. exec file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
  *
  * Licensed under the Apache License, Version 2.0 (the "License");
- * you may not use exec file except in compliance with the License.
+ * you may not use this file except in compliance with the License.
  * You may obtain a copy of the License at
  *
  *      http://www.apache.org/licenses/LICENSE-2.0
@@ -17,51 +17,82 @@
 package ovhcloud
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 
-	"github.com/gardener/controller-manager-library/pkg/logger"
-	"github.com/ovh/go-ovh/ovh"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/client-go/util/flowcontrol"
 
-	"github.com/gardener/external-dns-management/pkg/dns"
 	"github.com/gardener/external-dns-management/pkg/dns/provider"
 	"github.com/gardener/external-dns-management/pkg/dns/provider/raw"
 )
 
+// defaultMaxParallel is used if OVH_MAX_PARALLEL is not set.
+const defaultMaxParallel = 10
+
 type access struct {
-	client ovh.Client
-	metrics provider.Metrics
+	client      ovhClient
+	metrics     provider.Metrics
 	rateLimiter flowcontrol.RateLimiter
+	maxParallel int
+}
+
+func (a *access) parallelism() int {
+	if a.maxParallel <= 0 {
+		return defaultMaxParallel
+	}
+	return a.maxParallel
 }
 
 func getZones(a *access) ([]*zoneInfo, error) {
 	zs := &[]string{}
 	a.rateLimiter.Accept()
-	a.metrics.AddZoneRequests(zone, provider.M_LISTZONES, 1)
+	a.metrics.AddZoneRequests("", provider.M_LISTZONES, 1)
 	if err := a.client.Get(fmt.Sprintf("/domain/zone"), zs); err != nil {
 		return nil, fmt.Errorf("Error calling GET /domain/zone: %s", err)
 	}
 
-	zones := []*zoneInfo{}
-	for _, z := range zs {
-		zi := &zoneInfo{}
-		a.rateLimiter.Accept()
-		a.metrics.AddZoneRequests(zone, provider.M_LISTZONES, 1)
-		if err := a.client.Get(fmt.Sprintf("/domain/zone/%s", z), zi);  err != nil {
-			return nil, fmt.Errorf("Error calling GET /domain/zone/%s: %s", z, err)
+	zones := make([]*zoneInfo, len(*zs))
+	g, ctx := errgroup.WithContext(context.Background())
+	sem := make(chan struct{}, a.parallelism())
+dispatch:
+	for i, z := range *zs {
+		i, z := i, z
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case sem <- struct{}{}:
 		}
-		zones = append(zones, zi)
+		g.Go(func() error {
+			defer func() { <-sem }()
+			zi := &zoneInfo{}
+			a.rateLimiter.Accept()
+			a.metrics.AddZoneRequests(z, provider.M_LISTZONES, 1)
+			if err := a.client.Get(fmt.Sprintf("/domain/zone/%s", z), zi); err != nil {
+				return fmt.Errorf("Error calling GET /domain/zone/%s: %s", z, err)
+			}
+			zones[i] = zi
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
 	}
 
 	return zones, nil
 }
 
 func createRecordSet(a *access, zone, name, value, fieldType string, ttl int64) error {
+	if err := validateRecordTarget(fieldType, value); err != nil {
+		return err
+	}
+
 	r := recordInfo{
 		FieldType: fieldType,
 		SubDomain: name,
-		Target: value,
-		Ttl: ttl,
+		Target:    value,
+		Ttl:       ttl,
 	}
 
 	endpoint := fmt.Sprintf("/domain/zone/%s/record",
@@ -78,7 +109,7 @@ func createRecordSet(a *access, zone, name, value, fieldType string, ttl int64)
 }
 
 func getRecordSet(a *access, zone, subDomain, value, fieldType string) (*recordInfo, error) {
-	records, err := a.getRecordSets(zone, subDomain, fieldType)
+	records, err := getRecordSets(a, zone, subDomain, fieldType)
 	if err != nil {
 		return nil, err
 	}
@@ -93,13 +124,17 @@ func getRecordSet(a *access, zone, subDomain, value, fieldType string) (*recordI
 }
 
 func updateRecordSet(a *access, zone, subDomain, value, fieldType string, ttl int64) error {
-	record, err := a.getRecordSet(zone, subDomain, fieldType)
+	if err := validateRecordTarget(fieldType, value); err != nil {
+		return err
+	}
+
+	record, err := getRecordSet(a, zone, subDomain, value, fieldType)
 	if err != nil {
 		return err
 	}
 
 	if record == nil {
-		return nil, fmt.Errorf(
+		return fmt.Errorf(
 			"Could not find record for zone %s, subDomain %s and type %s",
 			zone,
 			subDomain,
@@ -126,7 +161,7 @@ func updateRecordSet(a *access, zone, subDomain, value, fieldType string, ttl in
 }
 
 func deleteRecordSet(a *access, zone, subDomain, value, fieldType string) error {
-	record, err := a.getRecordSet(zone, subDomain, fieldType)
+	record, err := getRecordSet(a, zone, subDomain, value, fieldType)
 	if err != nil {
 		return err
 	}
@@ -142,7 +177,7 @@ func deleteRecordSet(a *access, zone, subDomain, value, fieldType string) error
 
 	a.rateLimiter.Accept()
 	a.metrics.AddZoneRequests(zone, provider.M_DELETERECORDS, 1)
-	if err := a.client.Delete(endpoint, ri); err != nil {
+	if err := a.client.Delete(endpoint, nil); err != nil {
 		return fmt.Errorf("Error calling DELETE %s: %s", endpoint, err)
 	}
 
@@ -164,22 +199,37 @@ func getRecordSets(a *access, zone, subDomain, fieldType string) ([]*recordInfo,
 		return nil, fmt.Errorf("Error calling GET %s: %s", endpoint, err)
 	}
 
-	records := []*recordInfo{}
-	for _, r := range rs {
-		ri := &recordInfo{}
-		endpoint := fmt.Sprintf("/domain/zone/%s/record/%i", url.PathEscape(zone), r)
-		a.rateLimiter.Accept()
-		a.metrics.AddZoneRequests(zone, provider.LISTRECORDS, 1)
-		if err := a.client.Get(endpoint, ri);  err != nil {
-			return nil, fmt.Errorf("Error calling GET %s: %s", endpoint, err)
+	records := make([]*recordInfo, len(*rs))
+	g, ctx := errgroup.WithContext(context.Background())
+	sem := make(chan struct{}, a.parallelism())
+dispatch:
+	for i, r := range *rs {
+		i, r := i, r
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case sem <- struct{}{}:
 		}
-		records = append(records, ri)
+		g.Go(func() error {
+			defer func() { <-sem }()
+			ri := &recordInfo{}
+			endpoint := fmt.Sprintf("/domain/zone/%s/record/%d", url.PathEscape(zone), r)
+			a.rateLimiter.Accept()
+			a.metrics.AddZoneRequests(zone, provider.M_LISTRECORDS, 1)
+			if err := a.client.Get(endpoint, ri); err != nil {
+				return fmt.Errorf("Error calling GET %s: %s", endpoint, err)
+			}
+			records[i] = ri
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
 	}
 
 	return records, nil
 }
 
-
 func refreshZone(a *access, zone string) error {
 	endpoint := fmt.Sprintf("/domain/zone/%s/refresh",
 		url.PathEscape(zone),
@@ -194,7 +244,6 @@ func refreshZone(a *access, zone string) error {
 	return nil
 }
 
-
 type recordInfo struct {
 	FieldType string `json:"fieldType,omitempty"`
 	Id        int64  `json:"id,omitempty"`
@@ -221,6 +270,6 @@ type zoneInfo struct {
 	DnssecSupported bool     `json:"dnssecSupported"`
 	HasDnsAnycast   bool     `json:"hasDnsAnycast"`
 	LastUpdate      string   `json:"lastUpdate"`
-	Name            string    `json:"name"`
+	Name            string   `json:"name"`
 	NameServers     []string `json:"nameServers"`
 }
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package ovhcloud
+
+import "fmt"
+
+// recordChange describes a single record-set operation to be applied to a zone.
+type recordChange struct {
+	name      string
+	value     string
+	fieldType string
+	ttl       int64
+}
+
+// changeBatch groups all record changes for one zone reconcile so they can be applied
+// together and, if atomic is set, rolled back on partial failure. Only a single
+// refreshZone call is issued once every change in the batch has been applied.
+type changeBatch struct {
+	zone   string
+	atomic bool
+
+	creates []recordChange
+	updates []recordChange
+	deletes []recordChange
+}
+
+func newChangeBatch(zone string, atomic bool) *changeBatch {
+	return &changeBatch{zone: zone, atomic: atomic}
+}
+
+func (b *changeBatch) addCreate(name, value, fieldType string, ttl int64) {
+	b.creates = append(b.creates, recordChange{name: name, value: value, fieldType: fieldType, ttl: ttl})
+}
+
+func (b *changeBatch) addUpdate(name, value, fieldType string, ttl int64) {
+	b.updates = append(b.updates, recordChange{name: name, value: value, fieldType: fieldType, ttl: ttl})
+}
+
+func (b *changeBatch) addDelete(name, value, fieldType string, ttl int64) {
+	b.deletes = append(b.deletes, recordChange{name: name, value: value, fieldType: fieldType, ttl: ttl})
+}
+
+// collapseNoops drops delete+create pairs that share name/type/target/ttl: applying
+// both would leave the record exactly as it was, at the cost of two OVH API calls.
+func (b *changeBatch) collapseNoops() {
+	remainingDeletes := b.deletes[:0]
+	for _, d := range b.deletes {
+		collapsed := false
+		remainingCreates := b.creates[:0]
+		for _, c := range b.creates {
+			if !collapsed && c == d {
+				collapsed = true
+				continue
+			}
+			remainingCreates = append(remainingCreates, c)
+		}
+		b.creates = remainingCreates
+		if !collapsed {
+			remainingDeletes = append(remainingDeletes, d)
+		}
+	}
+	b.deletes = remainingDeletes
+}
+
+// apply executes the batch against the OVH API: all creates, then all updates, then
+// all deletes, followed by a single zone refresh. If atomic is enabled, a failing
+// create, update or delete rolls back every change already applied in this batch, in
+// reverse order, before the error is returned: applied creates are deleted, applied
+// updates are restored to their previous value, and applied deletes are recreated.
+func (b *changeBatch) apply(a *access) error {
+	b.collapseNoops()
+
+	var undo []func() error
+	rollback := func(cause error) error {
+		if !b.atomic {
+			return cause
+		}
+		for i := len(undo) - 1; i >= 0; i-- {
+			if err := undo[i](); err != nil {
+				return fmt.Errorf("%s (rollback also failed: %s)", cause, err)
+			}
+		}
+		return cause
+	}
+
+	for _, c := range b.creates {
+		if err := createRecordSet(a, b.zone, c.name, c.value, c.fieldType, c.ttl); err != nil {
+			return rollback(fmt.Errorf("create DNS zone record failed for %s. Details: %s", b.zone, err))
+		}
+		c := c
+		undo = append(undo, func() error { return deleteRecordSet(a, b.zone, c.name, c.value, c.fieldType) })
+	}
+	for _, c := range b.updates {
+		previous, err := getRecordSet(a, b.zone, c.name, c.value, c.fieldType)
+		if err != nil {
+			return rollback(fmt.Errorf("update DNS zone record failed for %s. Details: %s", b.zone, err))
+		}
+		if err := updateRecordSet(a, b.zone, c.name, c.value, c.fieldType, c.ttl); err != nil {
+			return rollback(fmt.Errorf("update DNS zone record failed for %s. Details: %s", b.zone, err))
+		}
+		if previous != nil {
+			name, value, fieldType := c.name, c.value, c.fieldType
+			prevTtl := previous.Ttl
+			undo = append(undo, func() error { return updateRecordSet(a, b.zone, name, value, fieldType, prevTtl) })
+		}
+	}
+	for _, c := range b.deletes {
+		if err := deleteRecordSet(a, b.zone, c.name, c.value, c.fieldType); err != nil {
+			return rollback(fmt.Errorf("delete DNS zone record failed for %s. Details: %s", b.zone, err))
+		}
+		c := c
+		undo = append(undo, func() error { return createRecordSet(a, b.zone, c.name, c.value, c.fieldType, c.ttl) })
+	}
+
+	if len(b.creates)+len(b.updates)+len(b.deletes) > 0 {
+		if err := refreshZone(a, b.zone); err != nil {
+			return fmt.Errorf("refresh DNS zone failed for %s. Details: %s", b.zone, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package ovhcloud
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ovh/go-ovh/ovh"
+)
+
+// AccessRule is a single entry of an OVH credential's access rule list, e.g.
+// {"GET", "/domain/zone/*"}.
+type AccessRule struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// DefaultZoneAccessRules scopes a requested consumer key to exactly what the DNS
+// provider needs, following least-privilege: read/write access to zones and their
+// records, nothing else.
+var DefaultZoneAccessRules = []AccessRule{
+	{Method: "GET", Path: "/domain/zone/*"},
+	{Method: "POST", Path: "/domain/zone/*"},
+	{Method: "PUT", Path: "/domain/zone/*"},
+	{Method: "DELETE", Path: "/domain/zone/*"},
+}
+
+type credentialRequest struct {
+	AccessRules []AccessRule `json:"accessRules"`
+}
+
+type credentialResponse struct {
+	ConsumerKey   string `json:"consumerKey"`
+	State         string `json:"state"`
+	ValidationURL string `json:"validationUrl"`
+}
+
+type currentCredential struct {
+	Status string `json:"status"`
+}
+
+const currentCredentialPollInterval = 5 * time.Second
+
+// RequestConsumerKey performs the OVH credential-request flow for a fresh application
+// key/secret pair. It requests a consumer key scoped to requiredAccessRules, prints
+// validationURL to stderr for a human to approve, and polls /auth/currentCredential
+// until the key is validated, or until ctx is done. The consumer key and validation
+// URL are also returned when validation fails or ctx is done, once they are known.
+func RequestConsumerKey(ctx context.Context, endpoint, appKey, appSecret string, requiredAccessRules []AccessRule) (consumerKey, validationURL string, err error) {
+	client, err := ovh.NewClient(endpoint, appKey, appSecret, "")
+	if err != nil {
+		return "", "", fmt.Errorf("Error creating ovh client: %s", err)
+	}
+
+	req := credentialRequest{AccessRules: requiredAccessRules}
+	resp := credentialResponse{}
+	if err := client.Post("/auth/credential", req, &resp); err != nil {
+		return "", "", fmt.Errorf("Error calling POST /auth/credential: %s", err)
+	}
+
+	validatingClient, err := ovh.NewClient(endpoint, appKey, appSecret, resp.ConsumerKey)
+	if err != nil {
+		return resp.ConsumerKey, resp.ValidationURL, fmt.Errorf("Error creating ovh client: %s", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Approve this consumer key at: %s\n", resp.ValidationURL)
+	if err := waitForValidation(ctx, validatingClient); err != nil {
+		return resp.ConsumerKey, resp.ValidationURL, err
+	}
+
+	return resp.ConsumerKey, resp.ValidationURL, nil
+}
+
+func waitForValidation(ctx context.Context, client *ovh.Client) error {
+	ticker := time.NewTicker(currentCredentialPollInterval)
+	defer ticker.Stop()
+
+	for {
+		cc := currentCredential{}
+		if err := client.Get("/auth/currentCredential", &cc); err != nil {
+			return fmt.Errorf("Error calling GET /auth/currentCredential: %s", err)
+		}
+		if cc.Status == "validated" {
+			return nil
+		}
+		if cc.Status == "refused" || cc.Status == "expired" {
+			return fmt.Errorf("consumer key was %s", cc.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package ovhcloud
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/gardener/external-dns-management/pkg/dns/provider"
+)
+
+var _ provider.DNSSECHandler = &Handler{}
+
+// dnssecStatus mirrors the OVH GET /domain/zone/{zone}/dnssec response.
+type dnssecStatus struct {
+	Status string `json:"status"`
+}
+
+// dsRecordInfo mirrors a single entry of the OVH DS record keyset.
+type dsRecordInfo struct {
+	KeyTag     int    `json:"keyTag"`
+	Algorithm  int    `json:"algorithm"`
+	DigestType int    `json:"digestType"`
+	Digest     string `json:"digest"`
+}
+
+// EnableDNSSEC turns on DNSSEC signing for the zone and returns the DS record set to
+// publish at the parent zone/registrar.
+func (h *Handler) EnableDNSSEC(zone string) ([]provider.DSRecord, error) {
+	endpoint := fmt.Sprintf("/domain/zone/%s/dnssec", url.PathEscape(zone))
+
+	h.access.rateLimiter.Accept()
+	if err := h.access.client.Post(endpoint, nil, nil); err != nil {
+		return nil, fmt.Errorf("Error calling POST %s: %s", endpoint, err)
+	}
+
+	return h.GetDSRecords(zone)
+}
+
+// DisableDNSSEC turns off DNSSEC signing for the zone.
+func (h *Handler) DisableDNSSEC(zone string) error {
+	endpoint := fmt.Sprintf("/domain/zone/%s/dnssec", url.PathEscape(zone))
+
+	h.access.rateLimiter.Accept()
+	if err := h.access.client.Delete(endpoint, nil); err != nil {
+		return fmt.Errorf("Error calling DELETE %s: %s", endpoint, err)
+	}
+
+	return nil
+}
+
+// ApplyDNSSEC reconciles DNSSEC signing for zone against a desired state read from the
+// source.DNSSEC_ANNOTATION value of a DNSEntry/zone ("enabled", "disabled", or "" /
+// nil for no opinion). It returns the DS record set to publish in the DNSEntry status
+// when DNSSEC ends up enabled, and an empty slice otherwise.
+func (h *Handler) ApplyDNSSEC(zone string, desired *string) ([]provider.DSRecord, error) {
+	if desired == nil {
+		return h.GetDSRecords(zone)
+	}
+
+	switch *desired {
+	case "enabled":
+		return h.EnableDNSSEC(zone)
+	case "disabled":
+		if err := h.DisableDNSSEC(zone); err != nil {
+			return nil, err
+		}
+		return []provider.DSRecord{}, nil
+	default:
+		return nil, fmt.Errorf("invalid DNSSEC state %q for zone %s, must be \"enabled\" or \"disabled\"", *desired, zone)
+	}
+}
+
+// GetDSRecords returns the current DS record set for the zone. It returns an empty
+// slice if DNSSEC is not enabled for the zone.
+func (h *Handler) GetDSRecords(zone string) ([]provider.DSRecord, error) {
+	status := &dnssecStatus{}
+	endpoint := fmt.Sprintf("/domain/zone/%s/dnssec", url.PathEscape(zone))
+
+	h.access.rateLimiter.Accept()
+	if err := h.access.client.Get(endpoint, status); err != nil {
+		return nil, fmt.Errorf("Error calling GET %s: %s", endpoint, err)
+	}
+
+	if status.Status != "enabled" {
+		return []provider.DSRecord{}, nil
+	}
+
+	dsInfos := &[]dsRecordInfo{}
+	dsEndpoint := fmt.Sprintf("/domain/zone/%s/dnssec/dsRecords", url.PathEscape(zone))
+
+	h.access.rateLimiter.Accept()
+	if err := h.access.client.Get(dsEndpoint, dsInfos); err != nil {
+		return nil, fmt.Errorf("Error calling GET %s: %s", dsEndpoint, err)
+	}
+
+	records := make([]provider.DSRecord, 0, len(*dsInfos))
+	for _, ds := range *dsInfos {
+		records = append(records, provider.DSRecord{
+			KeyTag:     ds.KeyTag,
+			Algorithm:  ds.Algorithm,
+			DigestType: ds.DigestType,
+			Digest:     ds.Digest,
+		})
+	}
+
+	return records, nil
+}
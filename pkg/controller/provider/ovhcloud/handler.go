@@ -1,5 +1,5 @@
 /*
- * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. h file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
  *
  * Licensed under the Apache License, Version 2.0 (the "License");
  * you may not use this file except in compliance with the License.
@@ -19,7 +19,8 @@ package ovhcloud
 import (
 	"context"
 	"fmt"
-	"strings"
+	"strconv"
+	"time"
 
 	"github.com/gardener/controller-manager-library/pkg/logger"
 	"github.com/ovh/go-ovh/ovh"
@@ -33,14 +34,14 @@ type Handler struct {
 	provider.ZoneCache
 	provider.DefaultDNSHandler
 
-	access *access
-	config *provider.DNSHandlerConfig
-	ctx    context.Context
+	access        *access
+	config        *provider.DNSHandlerConfig
+	ctx           context.Context
+	atomicChanges bool
 }
 
 var _ provider.DNSHandler = &Handler{}
 
-
 // NewHandler constructs a new DNSHandler object.
 func NewHandler(config *provider.DNSHandlerConfig) (provider.DNSHandler, error) {
 	authConfig, err := readAuthConfig(config)
@@ -48,20 +49,49 @@ func NewHandler(config *provider.DNSHandlerConfig) (provider.DNSHandler, error)
 		return nil, err
 	}
 
-	client, err := createOvhcloudClient(config.Logger, authConfig)
+	client, err := createOvhcloudClient(config.Logger, authConfig, config.Metrics)
 	if err != nil {
 		return nil, err
 	}
 
+	maxParallel := defaultMaxParallel
+	if p := config.GetProperty("OVH_MAX_PARALLEL"); p != "" {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OVH_MAX_PARALLEL value %q: %s", p, err)
+		}
+		maxParallel = v
+	}
+
+	maxRetries := defaultMaxRetries
+	if p := config.GetProperty("OVH_MAX_RETRIES"); p != "" {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OVH_MAX_RETRIES value %q: %s", p, err)
+		}
+		maxRetries = v
+	}
+
+	retryBaseDelay := defaultRetryBaseDelay
+	if p := config.GetProperty("OVH_RETRY_BASE_DELAY"); p != "" {
+		v, err := time.ParseDuration(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OVH_RETRY_BASE_DELAY value %q: %s", p, err)
+		}
+		retryBaseDelay = v
+	}
+
 	h := &Handler{
 		DefaultDNSHandler: provider.NewDefaultDNSHandler(TYPE_CODE),
 		config:            config,
 		ctx:               config.Context,
-		access:	           &access{
-			client: client,
-			metrics: config.Metrics,
-			rateLimiter: h.config.RateLimiter,
-		}
+		atomicChanges:     config.GetProperty("OVH_ATOMIC_CHANGES") == "true",
+		access: &access{
+			client:      newAdaptiveClient(client, config.Metrics, maxRetries, retryBaseDelay),
+			metrics:     config.Metrics,
+			rateLimiter: config.RateLimiter,
+			maxParallel: maxParallel,
+		},
 	}
 
 	h.ZoneCache, err = provider.NewZoneCache(config.CacheConfig, config.Metrics, nil, h.getZones, h.getZoneState)
@@ -98,16 +128,16 @@ func readAuthConfig(c *provider.DNSHandlerConfig) (*clientAuthConfig, error) {
 	}
 
 	authConfig := clientAuthConfig{
-		Endpoint          : endpoint,
-		ApplicationKey    : ak,
-		ApplicationSecret : as,
-		ConsumerKey       : ck,
+		Endpoint:          endpoint,
+		ApplicationKey:    ak,
+		ApplicationSecret: as,
+		ConsumerKey:       ck,
 	}
 
 	return &authConfig, nil
 }
 
-func createOvhcloudClient(logger logger.LogContext, clientAuthConfig *clientAuthConfig) (*ovh.Client, error) {
+func createOvhcloudClient(logger logger.LogContext, authConfig *clientAuthConfig, metrics provider.Metrics) (*ovh.Client, error) {
 	validEndpoint := false
 
 	ovhEndpoints := [7]string{
@@ -117,44 +147,46 @@ func createOvhcloudClient(logger logger.LogContext, clientAuthConfig *clientAuth
 		ovh.KimsufiEU,
 		ovh.KimsufiCA,
 		ovh.SoyoustartEU,
-		ovh.SoyoustartCA
+		ovh.SoyoustartCA,
 	}
 
 	for _, e := range ovhEndpoints {
-		if ovh.Endpoints[c.Endpoint] == e {
+		if ovh.Endpoints[authConfig.Endpoint] == e {
 			validEndpoint = true
 		}
 	}
 
 	if !validEndpoint {
-		return nil, fmt.Errorf("%s must be one of %#v endpoints\n", c.Endpoint, ovh.Endpoints)
+		return nil, fmt.Errorf("%s must be one of %#v endpoints\n", authConfig.Endpoint, ovh.Endpoints)
 	}
 
 	client, err := ovh.NewClient(
-		c.Endpoint,
-		c.ApplicationKey,
-		c.ApplicationSecret,
-		c.ConsumerKey,
+		authConfig.Endpoint,
+		authConfig.ApplicationKey,
+		authConfig.ApplicationSecret,
+		authConfig.ConsumerKey,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("Error getting ovh client: %q\n", err)
 	}
-	return client
+
+	client.Client.Transport = newHeaderThrottleTransport(client.Client.Transport, metrics)
+	return client, nil
 }
 
 // Release releases the zone cache.
 func (h *Handler) Release() {
-	h.cache.Release()
+	h.ZoneCache.Release()
 }
 
 // GetZones returns a list of hosted zones from the cache.
 func (h *Handler) GetZones() (provider.DNSHostedZones, error) {
-	return h.cache.GetZones()
+	return h.ZoneCache.GetZones()
 }
 
 func (h *Handler) getZones(cache provider.ZoneCache) (provider.DNSHostedZones, error) {
-	zones, err := h.access.getZones()
+	zones, err := getZones(h.access)
 	if err != nil {
 		return nil, fmt.Errorf("listing DNS zones failed. Details: %s", err)
 	}
@@ -162,7 +194,7 @@ func (h *Handler) getZones(cache provider.ZoneCache) (provider.DNSHostedZones, e
 	hostedZones := provider.DNSHostedZones{}
 	for _, z := range zones {
 		forwarded := []string{}
-		records, err := h.access.getRecordSets(z, "", "NS")
+		records, err := getRecordSets(h.access, z.Name, "", "NS")
 		if err != nil {
 			return nil, fmt.Errorf("listing DNS zone records failed for zone %s. Details: %s", z, err)
 		}
@@ -191,94 +223,115 @@ func (h *Handler) getZones(cache provider.ZoneCache) (provider.DNSHostedZones, e
 
 // GetZoneState returns the state for a given zone.
 func (h *Handler) GetZoneState(zone provider.DNSHostedZone) (provider.DNSZoneState, error) {
-	return h.cache.GetZoneState(zone)
+	return h.ZoneCache.GetZoneState(zone)
 }
 
 func (h *Handler) getZoneState(zone provider.DNSHostedZone, cache provider.ZoneCache) (provider.DNSZoneState, error) {
 	dnssets := dns.DNSSets{}
 
-	recordSetHandler := func(recordSet *recordsets.RecordSet) error {
-		switch recordSet.Type {
-		case dns.RS_A, dns.RS_CNAME, dns.RS_TXT:
-			rs := dns.NewRecordSet(recordSet.Type, int64(recordSet.TTL), nil)
-			for _, record := range recordSet.Records {
-				value := record
-				if recordSet.Type == dns.RS_CNAME {
-					value = dns.NormalizeHostname(value)
-				}
-				rs.Add(&dns.Record{Value: value})
+	for fieldType := range managedRecordTypes {
+		records, err := getRecordSets(h.access, zone.Id(), "", fieldType)
+		if err != nil {
+			return nil, fmt.Errorf("Listing DNS zone records failed for %s. Details: %s", zone.Id(), err.Error())
+		}
+
+		rsets := map[string]*dns.RecordSet{}
+		for _, r := range records {
+			name := dns.AlignHostname(fmt.Sprintf("%s.%s", r.SubDomain, r.Zone))
+			if fieldType == dns.RS_NS && name == dns.AlignHostname(zone.Domain()) {
+				// apex NS records are the zone's own delegation and are not managed
+				// as regular DNSEntry records.
+				continue
+			}
+
+			rs := rsets[name]
+			if rs == nil {
+				rs = dns.NewRecordSet(fieldType, r.Ttl, nil)
+				rsets[name] = rs
 			}
-			dnssets.AddRecordSetFromProvider(recordSet.Name, rs)
+
+			value := r.Target
+			if fieldType == dns.RS_CNAME {
+				value = dns.NormalizeHostname(value)
+			}
+			rs.Add(&dns.Record{Value: value})
 		}
-		return nil
-	}
 
-	h.config.RateLimiter.Accept()
-	if err := h.client.ForEachRecordSet(zone.Id(), recordSetHandler); err != nil {
-		return nil, fmt.Errorf("Listing DNS zones failed for %s. Details: %s", zone.Id(), err.Error())
+		for name, rs := range rsets {
+			dnssets.AddRecordSetFromProvider(name, rs)
+		}
 	}
 
 	return provider.NewDNSZoneState(dnssets), nil
 }
 
-func (h *Handler) ReportZoneStateConflict(zone provider.DNSHostedZone, err error) bool {
-	return h.cache.ReportZoneStateConflict(zone, err)
+// managedRecordTypes are the OVH record types synced into DNSEntry state.
+var managedRecordTypes = map[string]bool{
+	dns.RS_A:     true,
+	dns.RS_AAAA:  true,
+	dns.RS_CNAME: true,
+	dns.RS_TXT:   true,
+	dns.RS_MX:    true,
+	dns.RS_SRV:   true,
+	dns.RS_CAA:   true,
+	dns.RS_TLSA:  true,
+	dns.RS_NS:    true,
 }
 
-// ExecuteRequests applies a given change request to a given hosted zone.
-func (h *Handler) ExecuteRequests(logger logger.LogContext, zone provider.DNSHostedZone, state provider.DNSZoneState, reqs []*provider.ChangeRequest) error {
-	err := h.executeRequests(logger, zone, state, reqs)
-	h.cache.ApplyRequests(logger, err, zone, reqs)
-	return err
+func isManagedRecordType(t string) bool {
+	return managedRecordTypes[t]
 }
 
+func (h *Handler) ReportZoneStateConflict(zone provider.DNSHostedZone, err error) bool {
+	return h.ZoneCache.ReportZoneStateConflict(zone, err)
+}
 
+// ExecuteRequests applies a given change request to a given hosted zone.
 func (h *Handler) ExecuteRequests(logger logger.LogContext, zone provider.DNSHostedZone, state provider.DNSZoneState, reqs []*provider.ChangeRequest) error {
 	err := h.executeRequests(logger, zone, state, reqs)
-	h.cache.ApplyRequests(logger, err, zone, reqs)
+	h.ZoneCache.ApplyRequests(logger, err, zone, reqs)
 	return err
 }
 
+// executeRequests collects all record changes for the zone into a single changeBatch
+// and applies it in one go, so the zone is only refreshed once per reconcile.
 func (h *Handler) executeRequests(logger logger.LogContext, zone provider.DNSHostedZone, state provider.DNSZoneState, reqs []*provider.ChangeRequest) error {
 	if h.config.DryRun {
 		logger.Infof("no changes in dryrun mode for OVHcloud")
 		return nil
 	}
 
-	updated := false
-	for _, r := range reqs {
-		name, rset := dns.MapToProvider(req.Type, dnsset, this.zone.Domain())
+	batch := newChangeBatch(zone.Id(), h.atomicChanges)
+	for _, req := range reqs {
+		var dnsset *dns.DNSSet
+		var action string
+		switch req.Action {
+		case provider.R_CREATE, provider.R_UPDATE:
+			dnsset = req.Addition
+			action = "create/update"
+		case provider.R_DELETE:
+			dnsset = req.Deletion
+			action = "delete"
+		}
+
+		name, rset := dns.MapToProvider(req.Type, dnsset, zone.Domain())
 		name = dns.AlignHostname(name)
 		if len(rset.Records) == 0 {
-			return
+			continue
 		}
 
-		this.Infof("%s %s record set %s[%s]: %s(%d)", action, rset.Type, name, this.zone.Id(), rset.RecordString(), rset.TTL)
-		for i, r := range rset.Records {
-			updated = true
-			h.config.RateLimiter.Accept()
-			switch r.Action {
+		logger.Infof("%s %s record set %s[%s]: %s(%d)", action, rset.Type, name, zone.Id(), rset.RecordString(), rset.TTL)
+		for _, r := range rset.Records {
+			switch req.Action {
 			case provider.R_CREATE:
-				if err := access.createRecordSet(zone.Id(), name, r.Value, rset.Type, rset.TTL); err != nil {
-					return nil, fmt.Errorf("Create DNS zone record failed for %s. Details: %s", zone.Id(), err)
-				}
+				batch.addCreate(name, r.Value, rset.Type, rset.TTL)
 			case provider.R_UPDATE:
-				if err := access.updateRecordSet(zone.Id(), name, r.Value, rset.Type, rset.TTL); err != nil {
-					return nil, fmt.Errorf("Update DNS zone record failed for %s. Details: %s", zone.Id(), err)
-				}
+				batch.addUpdate(name, r.Value, rset.Type, rset.TTL)
 			case provider.R_DELETE:
-				if err := access.deleteRecordSet(zone.Id(), name, r.Value, rset.Type); err != nil {
-					return nil, fmt.Errorf("Delete DNS zone record failed for %s. Details: %s", zone.Id(), err)
-				}
+				batch.addDelete(name, r.Value, rset.Type, rset.TTL)
 			}
 		}
 	}
 
-	if updated {
-		h.config.RateLimiter.Accept()
-		if err := access.refreshZone(zone.Id()); err != nil {
-			return nil, fmt.Errorf("Refesh DNS zone failed for %s. Details: %s", zone.Id(), err)
-		}
-	}
-	return nil
+	return batch.apply(h.access)
 }
@@ -0,0 +1,37 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package ovhcloud
+
+import (
+	"testing"
+
+	"github.com/gardener/external-dns-management/pkg/dns"
+)
+
+func TestIsManagedRecordType(t *testing.T) {
+	for _, managed := range []string{
+		dns.RS_A, dns.RS_AAAA, dns.RS_CNAME, dns.RS_TXT, dns.RS_MX, dns.RS_SRV, dns.RS_CAA, dns.RS_TLSA, dns.RS_NS,
+	} {
+		if !isManagedRecordType(managed) {
+			t.Errorf("isManagedRecordType(%q) = false, want true", managed)
+		}
+	}
+
+	if isManagedRecordType("SOA") {
+		t.Errorf("isManagedRecordType(%q) = true, want false", "SOA")
+	}
+}
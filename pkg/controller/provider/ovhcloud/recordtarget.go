@@ -0,0 +1,67 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package ovhcloud
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gardener/external-dns-management/pkg/dns"
+)
+
+// validateRecordTarget checks that value is well-formed for the given OVH fieldType.
+// OVH stores MX/SRV/CAA/TLSA targets as the same space-separated zone-file syntax it
+// renders them back as, so there is nothing to transcode - but a malformed value would
+// otherwise only surface as an opaque 400 from the OVH API.
+func validateRecordTarget(fieldType, value string) error {
+	fields := strings.Fields(value)
+	switch fieldType {
+	case dns.RS_MX:
+		// "<priority> <host>"
+		if len(fields) != 2 {
+			return fmt.Errorf("MX target %q must be \"<priority> <host>\"", value)
+		}
+		if _, err := strconv.Atoi(fields[0]); err != nil {
+			return fmt.Errorf("MX target %q has an invalid priority: %s", value, err)
+		}
+	case dns.RS_SRV:
+		// "<priority> <weight> <port> <target>"
+		if len(fields) != 4 {
+			return fmt.Errorf("SRV target %q must be \"<priority> <weight> <port> <target>\"", value)
+		}
+		for _, f := range fields[:3] {
+			if _, err := strconv.Atoi(f); err != nil {
+				return fmt.Errorf("SRV target %q has a non-numeric priority/weight/port: %s", value, err)
+			}
+		}
+	case dns.RS_CAA:
+		// "<flags> <tag> <value>"
+		if len(fields) < 3 {
+			return fmt.Errorf("CAA target %q must be \"<flags> <tag> <value>\"", value)
+		}
+		if _, err := strconv.Atoi(fields[0]); err != nil {
+			return fmt.Errorf("CAA target %q has invalid flags: %s", value, err)
+		}
+	case dns.RS_TLSA:
+		// "<usage> <selector> <matching-type> <cert-data>"
+		if len(fields) != 4 {
+			return fmt.Errorf("TLSA target %q must be \"<usage> <selector> <matching-type> <cert-data>\"", value)
+		}
+	}
+	return nil
+}
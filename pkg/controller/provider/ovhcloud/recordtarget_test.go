@@ -0,0 +1,56 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package ovhcloud
+
+import (
+	"testing"
+
+	"github.com/gardener/external-dns-management/pkg/dns"
+)
+
+func TestValidateRecordTarget(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		fieldType string
+		value     string
+		wantErr   bool
+	}{
+		{name: "MX valid", fieldType: dns.RS_MX, value: "10 mail.example.com."},
+		{name: "MX missing host", fieldType: dns.RS_MX, value: "10", wantErr: true},
+		{name: "MX non-numeric priority", fieldType: dns.RS_MX, value: "abc mail.example.com.", wantErr: true},
+		{name: "SRV valid", fieldType: dns.RS_SRV, value: "10 20 5060 sip.example.com."},
+		{name: "SRV too few fields", fieldType: dns.RS_SRV, value: "10 20 5060", wantErr: true},
+		{name: "SRV non-numeric field", fieldType: dns.RS_SRV, value: "10 x 5060 sip.example.com.", wantErr: true},
+		{name: "CAA valid", fieldType: dns.RS_CAA, value: "0 issue \"letsencrypt.org\""},
+		{name: "CAA too few fields", fieldType: dns.RS_CAA, value: "0 issue", wantErr: true},
+		{name: "CAA non-numeric flags", fieldType: dns.RS_CAA, value: "x issue \"letsencrypt.org\"", wantErr: true},
+		{name: "TLSA valid", fieldType: dns.RS_TLSA, value: "3 1 1 abcdef0123456789"},
+		{name: "TLSA wrong field count", fieldType: dns.RS_TLSA, value: "3 1 abcdef0123456789", wantErr: true},
+		{name: "A untouched", fieldType: dns.RS_A, value: "not a valid IP but not validated here"},
+		{name: "CNAME untouched", fieldType: dns.RS_CNAME, value: "anything.example.com."},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateRecordTarget(tc.fieldType, tc.value)
+			if tc.wantErr && err == nil {
+				t.Fatalf("validateRecordTarget(%q, %q) = nil, want error", tc.fieldType, tc.value)
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("validateRecordTarget(%q, %q) = %v, want nil", tc.fieldType, tc.value, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package provider
+
+// DSRecord describes a single DS (Delegation Signer) record that must be copied to the
+// parent zone's registrar to complete a DNSSEC delegation.
+type DSRecord struct {
+	KeyTag     int
+	Algorithm  int
+	DigestType int
+	Digest     string
+}
+
+// DNSSECHandler is an optional capability a DNSHandler implementation can provide if
+// its backing DNS provider supports managing DNSSEC signing for a hosted zone. Handlers
+// that don't support DNSSEC simply don't implement this interface; callers should use a
+// type assertion to discover support.
+type DNSSECHandler interface {
+	// EnableDNSSEC turns on DNSSEC signing for the given zone and returns the DS
+	// record set to be published at the parent zone/registrar.
+	EnableDNSSEC(zone string) ([]DSRecord, error)
+	// DisableDNSSEC turns off DNSSEC signing for the given zone.
+	DisableDNSSEC(zone string) error
+	// GetDSRecords returns the current DS record set for the given zone. It returns
+	// an empty slice if DNSSEC is not enabled for the zone.
+	GetDSRecords(zone string) ([]DSRecord, error)
+}
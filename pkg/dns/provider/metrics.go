@@ -0,0 +1,37 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package provider
+
+// Metrics is implemented by the controller's metrics backend and lets a DNSHandler
+// report per-zone API usage without depending on a specific metrics library.
+type Metrics interface {
+	// AddZoneRequests records n requests of the given request type issued for zone.
+	// zone may be empty for requests not scoped to a single zone (e.g. listing zones).
+	AddZoneRequests(zone, requestType string, n int)
+}
+
+// Request types reported through Metrics.AddZoneRequests.
+const (
+	M_LISTZONES     = "list_zones"
+	M_LISTRECORDS   = "list_records"
+	M_CREATERECORDS = "create_records"
+	M_UPDATERECORDS = "update_records"
+	M_DELETERECORDS = "delete_records"
+	// M_THROTTLE is reported whenever a request had to be retried or delayed because
+	// the backing DNS provider's API is throttling this consumer.
+	M_THROTTLE = "throttle"
+)
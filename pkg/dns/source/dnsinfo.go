@@ -93,6 +93,17 @@ func (this *sourceReconciler) getDNSInfo(logger logger.LogContext, obj resources
 			}
 		}
 	}
+	if info.DNSSEC == nil {
+		a := annos[DNSSEC_ANNOTATION]
+		if a != "" {
+			switch a {
+			case "enabled", "disabled":
+				info.DNSSEC = &a
+			default:
+				return info, true, fmt.Errorf("invalid %s annotation value %q, must be \"enabled\" or \"disabled\"", DNSSEC_ANNOTATION, a)
+			}
+		}
+	}
 	return info, true, nil
 }
 